@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"greenlight.tlei.net/internal/validator"
+)
+
+// readString returns a string value from the query string, or defaultValue if no
+// matching key is found.
+func (app *application) readString(qs url.Values, key string, defaultValue string) string {
+	s := qs.Get(key)
+
+	if s == "" {
+		return defaultValue
+	}
+
+	return s
+}
+
+// readCSV splits a comma-separated query string value into a slice, or returns
+// defaultValue if no matching key is found.
+func (app *application) readCSV(qs url.Values, key string, defaultValue []string) []string {
+	csv := qs.Get(key)
+
+	if csv == "" {
+		return defaultValue
+	}
+
+	return strings.Split(csv, ",")
+}
+
+// readInt returns an int value from the query string, or defaultValue if no matching
+// key is found. Any value that cannot be parsed records a validation error instead of
+// returning an error, so the caller can surface it alongside any other bad filters.
+func (app *application) readInt(qs url.Values, key string, defaultValue int, v *validator.Validator) int {
+	s := qs.Get(key)
+
+	if s == "" {
+		return defaultValue
+	}
+
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		v.AddError(key, "must be an integer value")
+		return defaultValue
+	}
+
+	return i
+}
@@ -46,6 +46,10 @@ type config struct {
 	cors struct {
 		trustedOrigins []string
 	}
+	advanced struct {
+		throttlingMultiplier int
+		throttlingRetryAfter time.Duration
+	}
 }
 
 type application struct {
@@ -82,6 +86,9 @@ func main() {
 		return nil
 	})
 
+	flag.IntVar(&cfg.advanced.throttlingMultiplier, "throttling-multiplier", 8, "Per-CPU multiplier for concurrent in-flight request throttling")
+	flag.DurationVar(&cfg.advanced.throttlingRetryAfter, "throttling-retry-after", 30*time.Second, "Retry-After duration advertised when throttled")
+
 	displayVersion := flag.Bool("version", false, "Display version and exit")
 
 	flag.Parse()
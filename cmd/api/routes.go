@@ -1,6 +1,7 @@
 package main
 
 import (
+	"expvar"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
@@ -13,12 +14,30 @@ func (app *application) routes() http.Handler {
 	router.NotFound = http.HandlerFunc(app.notFoundResponse)
 	router.MethodNotAllowed = http.HandlerFunc(app.methodNotAllowedResponse)
 
+	// Each group gets its own throttler instance so a burst against one can't starve
+	// the others' share of the CPU-scaled concurrency budget.
+	healthThrottle := app.Throttle()
+	movieThrottle := app.Throttle()
+	authThrottle := app.Throttle()
+
 	// Healthcheck
-	router.HandlerFunc(http.MethodGet, "/v1/healthcheck", app.healthCheckHandler)
+	router.Handler(http.MethodGet, "/v1/healthcheck", healthThrottle(http.HandlerFunc(app.healthCheckHandler)))
 
 	// Movie Routes
-	router.HandlerFunc(http.MethodPost, "/v1/movies", app.createMovieHandler)
-	router.HandlerFunc(http.MethodGet, "/v1/movies/:id", app.showMovieHandler)
+	router.Handler(http.MethodPost, "/v1/movies", movieThrottle(app.requirePermission("movies:write", app.createMovieHandler)))
+	router.Handler(http.MethodGet, "/v1/movies", movieThrottle(app.requirePermission("movies:read", app.listMoviesHandler)))
+	router.Handler(http.MethodGet, "/v1/movies/:id", movieThrottle(app.requirePermission("movies:read", app.showMovieHandler)))
+	router.Handler(http.MethodPut, "/v1/movies/:id", movieThrottle(app.requirePermission("movies:write", app.updateMovieHandler)))
+	router.Handler(http.MethodPatch, "/v1/movies/:id", movieThrottle(app.requirePermission("movies:write", app.patchMovieHandler)))
+	router.Handler(http.MethodDelete, "/v1/movies/:id", movieThrottle(app.requirePermission("movies:write", app.deleteMovieHandler)))
+
+	// User & Token Routes
+	router.Handler(http.MethodPost, "/v1/users", authThrottle(http.HandlerFunc(app.registerUserHandler)))
+	router.Handler(http.MethodPut, "/v1/users/activated", authThrottle(http.HandlerFunc(app.activateUserHandler)))
+	router.Handler(http.MethodPost, "/v1/tokens/authentication", authThrottle(http.HandlerFunc(app.createAuthenticationTokenHandler)))
+
+	// Metrics
+	router.Handler(http.MethodGet, "/debug/vars", app.requirePermission("metrics:read", expvar.Handler().ServeHTTP))
 
-	return app.recoverPanic(router)
+	return app.metrics(app.recoverPanic(app.enableCORS(app.rateLimit(app.authenticate(router)))))
 }